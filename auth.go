@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// Token types distinguish a short-lived access token from a long-lived
+// refresh token so one can never be used in place of the other.
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// Claims is the JWT payload issued by Login and Refresh: sub identifies the
+// admin user, typ is either tokenTypeAccess or tokenTypeRefresh, exp is
+// enforced by jwt.RegisteredClaims.
+type Claims struct {
+	Sub string `json:"sub"`
+	Typ string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+type claimsKey struct{}
+
+func jwtSecret() []byte {
+	return []byte(viper.GetString("JWT_SECRET"))
+}
+
+func issueToken(subject string, ttl time.Duration, tokenType string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Sub: subject,
+		Typ: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret())
+}
+
+// parseToken verifies tokenString's signature and expiry and checks that its
+// typ claim matches wantType, so an access token can't be replayed as a
+// refresh token or vice versa.
+func parseToken(tokenString, wantType string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("неожиданный метод подписи: %v", t.Header["alg"])
+		}
+		return jwtSecret(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("невалидный токен")
+	}
+	if claims.Typ != wantType {
+		return nil, fmt.Errorf("неверный тип токена: %s", claims.Typ)
+	}
+
+	return claims, nil
+}
+
+// requireAuth protects a handler behind a JWT access token issued by Login
+// or Refresh.
+func requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			http.Error(w, "отсутствует токен авторизации", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := parseToken(tokenString, tokenTypeAccess)
+		if err != nil {
+			http.Error(w, "невалидный токен авторизации", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), claimsKey{}, claims)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (app *App) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "некорректное тело запроса", http.StatusBadRequest)
+		return
+	}
+
+	if req.Username != viper.GetString("ADMIN_USERNAME") || req.Password != viper.GetString("ADMIN_PASSWORD") {
+		http.Error(w, "неверный логин или пароль", http.StatusUnauthorized)
+		return
+	}
+
+	writeTokenPair(w, req.Username)
+}
+
+func (app *App) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "некорректное тело запроса", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := parseToken(req.RefreshToken, tokenTypeRefresh)
+	if err != nil {
+		http.Error(w, "невалидный refresh-токен", http.StatusUnauthorized)
+		return
+	}
+
+	writeTokenPair(w, claims.Sub)
+}
+
+func writeTokenPair(w http.ResponseWriter, subject string) {
+	accessToken, err := issueToken(subject, accessTokenTTL, tokenTypeAccess)
+	if err != nil {
+		http.Error(w, "не удалось выпустить токен", http.StatusInternalServerError)
+		return
+	}
+
+	refreshToken, err := issueToken(subject, refreshTokenTTL, tokenTypeRefresh)
+	if err != nil {
+		http.Error(w, "не удалось выпустить токен", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenTTL.Seconds()),
+	})
+}
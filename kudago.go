@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	kudagoBaseURL = "https://kudago.com/public-api/v1.4/places"
+
+	// defaultFetchWorkerCount is used when FETCH_WORKER_COUNT is unset.
+	defaultFetchWorkerCount = 4
+
+	// overallFetchTimeout bounds the whole fetchAllPlaces call, across every
+	// page and retry.
+	overallFetchTimeout = 30 * time.Second
+
+	// perPageTimeout bounds a single page request.
+	perPageTimeout = 5 * time.Second
+
+	maxPageRetries   = 5
+	baseRetryBackoff = 200 * time.Millisecond
+)
+
+// fetchWorkerCount returns FETCH_WORKER_COUNT if configured, otherwise
+// defaultFetchWorkerCount.
+func fetchWorkerCount() int {
+	if workers := viper.GetInt("FETCH_WORKER_COUNT"); workers > 0 {
+		return workers
+	}
+
+	return defaultFetchWorkerCount
+}
+
+// newDeadlineContext derives a context whose Done channel closes either when
+// the parent is cancelled or when timeout elapses, whichever comes first.
+func newDeadlineContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, timeout)
+}
+
+// fetchCoordinators tracks, per in-flight singleflight key, how many callers
+// are currently waiting on that fetch so its context can be cancelled once
+// the last of them disconnects, rather than being tied to whichever caller
+// happened to trigger it.
+type fetchCoordinators struct {
+	mu    sync.Mutex
+	byKey map[string]*fetchCoordinator
+}
+
+type fetchCoordinator struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// join registers callerCtx as interested in the fetch running under key and
+// returns the context to run that fetch against, plus a leave func the
+// caller must invoke (typically via defer) once it stops waiting on it. The
+// shared context is cancelled once every waiter has left, whichever waiter
+// leaves last, so a fetch survives as long as at least one caller is still
+// interested even if others have disconnected.
+func (f *fetchCoordinators) join(key string, callerCtx context.Context) (context.Context, func()) {
+	f.mu.Lock()
+	if f.byKey == nil {
+		f.byKey = make(map[string]*fetchCoordinator)
+	}
+	fc, ok := f.byKey[key]
+	if !ok {
+		ctx, cancel := newDeadlineContext(context.Background(), overallFetchTimeout)
+		fc = &fetchCoordinator{ctx: ctx, cancel: cancel}
+		f.byKey[key] = fc
+	}
+	fc.waiters++
+	f.mu.Unlock()
+
+	stop := make(chan struct{})
+	var once sync.Once
+	leave := func() {
+		once.Do(func() {
+			close(stop)
+			f.mu.Lock()
+			fc.waiters--
+			if fc.waiters == 0 {
+				fc.cancel()
+				delete(f.byKey, key)
+			}
+			f.mu.Unlock()
+		})
+	}
+
+	go func() {
+		select {
+		case <-callerCtx.Done():
+			leave()
+		case <-stop:
+		}
+	}()
+
+	return fc.ctx, leave
+}
+
+// rateLimitError signals a 429 response and how long the caller asked us to
+// wait before retrying.
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("превышен лимит запросов, повтор через %s", e.retryAfter)
+}
+
+type pageResult struct {
+	places []Place
+	next   string
+	err    error
+}
+
+// pageNotFoundError signals that page is past the end of the dataset.
+// KuDaGo is an offset-style paginated API that can respond 404 once a page
+// number runs past the last one, so this is treated as "end of data" rather
+// than an upstream failure.
+type pageNotFoundError struct {
+	page int
+}
+
+func (e *pageNotFoundError) Error() string {
+	return fmt.Sprintf("страница %d не найдена", e.page)
+}
+
+// fetchAllPlaces pulls every KuDaGo places page, fetching up to
+// fetchWorkerCount() pages concurrently. Pages are numbered sequentially, so a
+// window of consecutive page numbers can be requested in parallel; the first
+// page in the window whose "next" field is empty marks the end of the
+// dataset and any later page in that same window is discarded.
+func fetchAllPlaces(ctx context.Context) ([]Place, error) {
+	client := &http.Client{Timeout: perPageTimeout}
+	workers := fetchWorkerCount()
+
+	var allPlaces []Place
+	page := 210
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		window := make([]int, workers)
+		for i := range window {
+			window[i] = page + i
+		}
+
+		results := make([]pageResult, len(window))
+		var wg sync.WaitGroup
+		for i, p := range window {
+			wg.Add(1)
+			go func(i, p int) {
+				defer wg.Done()
+				places, next, err := fetchPageWithRetry(ctx, client, p)
+				results[i] = pageResult{places: places, next: next, err: err}
+			}(i, p)
+		}
+		wg.Wait()
+
+		done := false
+		for _, res := range results {
+			var notFound *pageNotFoundError
+			if errors.As(res.err, &notFound) {
+				done = true
+				break
+			}
+			if res.err != nil {
+				return nil, res.err
+			}
+
+			allPlaces = append(allPlaces, res.places...)
+
+			if res.next == "" {
+				done = true
+				break
+			}
+		}
+
+		if done {
+			break
+		}
+
+		page += workers
+	}
+
+	return allPlaces, nil
+}
+
+// fetchPageWithRetry fetches a single page, retrying with exponential
+// backoff and jitter when the upstream responds 429, honoring Retry-After
+// when present.
+func fetchPageWithRetry(ctx context.Context, client *http.Client, page int) ([]Place, string, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= maxPageRetries; attempt++ {
+		places, next, err := fetchPage(ctx, client, page)
+		if err == nil {
+			return places, next, nil
+		}
+
+		var rateLimited *rateLimitError
+		if !errors.As(err, &rateLimited) {
+			return nil, "", err
+		}
+		lastErr = err
+
+		backoff := rateLimited.retryAfter
+		if backoff <= 0 {
+			backoff = baseRetryBackoff * time.Duration(1<<attempt)
+		}
+		jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+
+		select {
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		case <-time.After(backoff + jitter):
+		}
+	}
+
+	return nil, "", fmt.Errorf("страница %d: превышено число повторов: %w", page, lastErr)
+}
+
+func fetchPage(ctx context.Context, client *http.Client, page int) ([]Place, string, error) {
+	url := fmt.Sprintf("%s?page=%d", kudagoBaseURL, page)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		io.Copy(io.Discard, resp.Body)
+		return nil, "", &rateLimitError{retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		io.Copy(io.Discard, resp.Body)
+		return nil, "", &pageNotFoundError{page: page}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading response body: %w", err)
+	}
+
+	var result struct {
+		Results []Place `json:"results"`
+		Next    string  `json:"next"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", fmt.Errorf("error unmarshalling response: %w", err)
+	}
+
+	return result.Results, result.Next, nil
+}
+
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if when, err := time.Parse(time.RFC1123, header); err == nil {
+		return time.Until(when)
+	}
+
+	return 0
+}
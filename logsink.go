@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/valyamoro/pkg/repository"
+)
+
+const (
+	logSinkWorkers      = 2
+	logSinkBufferSize   = 100
+	logSinkBatchSize    = 20
+	logSinkFlushInterval = 2 * time.Second
+)
+
+// LogSink batches ExecutionLog entries written to it and flushes them to
+// Postgres whenever a batch fills up or logSinkFlushInterval elapses,
+// whichever comes first. Start launches logSinkWorkers consumers, each
+// accounted for once in wg — not once per message — so Shutdown can wait
+// for every worker to drain and exit cleanly.
+type LogSink struct {
+	repo   *repository.ExecutionLogRepository
+	in     chan ExecutionLog
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewLogSink(repo *repository.ExecutionLogRepository) *LogSink {
+	return &LogSink{
+		repo: repo,
+		in:   make(chan ExecutionLog, logSinkBufferSize),
+	}
+}
+
+// Start launches the consumer goroutines. Call it once at boot.
+func (s *LogSink) Start() {
+	for i := 0; i < logSinkWorkers; i++ {
+		s.wg.Add(1)
+		go s.consume()
+	}
+}
+
+// Add enqueues an entry for batched insertion. It blocks if every worker is
+// busy and the buffer is full. Add is a no-op once Shutdown has been
+// called: a handler's own fetch deadline can legitimately outlive the
+// server's shutdown grace period, so it must be safe for Add to still be
+// called after s.in has been closed.
+func (s *LogSink) Add(entry ExecutionLog) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+	s.in <- entry
+}
+
+func (s *LogSink) consume() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(logSinkFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]ExecutionLog, 0, logSinkBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.repo.InsertBatch(context.Background(), batch); err != nil {
+			log.Printf("Ощибка записи лога: %s\n", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-s.in:
+			if !ok {
+				flush()
+				return
+			}
+
+			batch = append(batch, entry)
+			if len(batch) >= logSinkBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Shutdown stops accepting new entries, flushes whatever is buffered, and
+// returns once every worker has exited or ctx is done, whichever comes
+// first.
+func (s *LogSink) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	close(s.in)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
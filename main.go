@@ -3,30 +3,131 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/csv"
 	"encoding/gob"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
-	"sync"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/viper"
+	"github.com/valyamoro/pkg/cache"
 	"github.com/valyamoro/pkg/database"
+	"github.com/valyamoro/pkg/repository"
+	"golang.org/x/sync/singleflight"
 )
 
 type Place struct {
-	Id int
-	Title string
-	Slug string
-	Address string
-	Phone string
-	Subway string
-	IsClosed bool
-	Location string
+	Id int `protobuf:"1"`
+	Title string `protobuf:"2"`
+	Slug string `protobuf:"3"`
+	Address string `protobuf:"4"`
+	Phone string `protobuf:"5"`
+	Subway string `protobuf:"6"`
+	IsClosed bool `protobuf:"7"`
+	Location string `protobuf:"8"`
+}
+
+// toRepositoryPlaces maps the KuDaGo-facing Place into the repository's
+// persistence model so a fetched page can be upserted into the places table.
+func toRepositoryPlaces(places []Place) []repository.Place {
+	result := make([]repository.Place, len(places))
+	for i, p := range places {
+		result[i] = repository.Place{
+			Id: p.Id,
+			Title: p.Title,
+			Slug: p.Slug,
+			Address: p.Address,
+			Phone: p.Phone,
+			Subway: p.Subway,
+			IsClosed: p.IsClosed,
+			Location: p.Location,
+		}
+	}
+	return result
+}
+
+func (p Place) csvHeader() []string {
+	return []string{"id", "title", "slug", "address", "phone", "subway", "is_closed", "location"}
+}
+
+func (p Place) csvRow() []string {
+	return []string{
+		strconv.Itoa(p.Id),
+		p.Title,
+		p.Slug,
+		p.Address,
+		p.Phone,
+		p.Subway,
+		strconv.FormatBool(p.IsClosed),
+		p.Location,
+	}
+}
+
+// protoMarshal encodes the place as a protobuf message, reading the field
+// number for each field from its `protobuf` struct tag so the wire layout
+// can never drift from the tags as fields are added, removed, or reordered.
+func (p Place) protoMarshal() []byte {
+	var buf bytes.Buffer
+
+	v := reflect.ValueOf(p)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("protobuf")
+		if tag == "" {
+			continue
+		}
+
+		fieldNum, err := strconv.Atoi(tag)
+		if err != nil {
+			continue
+		}
+
+		switch fv := v.Field(i); fv.Kind() {
+		case reflect.Int:
+			writeVarintField(&buf, fieldNum, uint64(fv.Int()))
+		case reflect.String:
+			writeStringField(&buf, fieldNum, fv.String())
+		case reflect.Bool:
+			if fv.Bool() {
+				writeVarintField(&buf, fieldNum, 1)
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func writeVarintField(buf *bytes.Buffer, fieldNum int, value uint64) {
+	var tagBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tagBuf[:], uint64(fieldNum<<3))
+	buf.Write(tagBuf[:n])
+
+	var valBuf [binary.MaxVarintLen64]byte
+	n = binary.PutUvarint(valBuf[:], value)
+	buf.Write(valBuf[:n])
+}
+
+func writeStringField(buf *bytes.Buffer, fieldNum int, value string) {
+	var tagBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tagBuf[:], uint64(fieldNum<<3|2))
+	buf.Write(tagBuf[:n])
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	n = binary.PutUvarint(lenBuf[:], uint64(len(value)))
+	buf.Write(lenBuf[:n])
+
+	buf.WriteString(value)
 }
 
 type Serializer[T any] interface {
@@ -34,6 +135,21 @@ type Serializer[T any] interface {
 	Deserialize(data []byte) (T, error)
 }
 
+// StreamingSerializer is implemented by serializers that can write records
+// one at a time instead of buffering the whole slice into memory first.
+type StreamingSerializer[T any] interface {
+	SerializeStream(w io.Writer, data []T) error
+}
+
+type csvMarshaler interface {
+	csvHeader() []string
+	csvRow() []string
+}
+
+type protoMarshaler interface {
+	protoMarshal() []byte
+}
+
 type JSONSerializer[T any] struct {}
 
 func (js JSONSerializer[T]) Serialize(data []T) ([]byte, error) {
@@ -56,11 +172,117 @@ func (gs GobSerializer[T]) Serialize(data []T) ([]byte, error) {
 }
 
 func (gs GobSerializer[T]) Deserialize(data []byte) (T, error) {
-	var result T 
+	var result T
 	reader := bytes.NewReader(data)
 	decoder := gob.NewDecoder(reader)
 	err := decoder.Decode(&result)
-	return result, err 
+	return result, err
+}
+
+type NDJSONSerializer[T any] struct {}
+
+func (ns NDJSONSerializer[T]) Serialize(data []T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ns.SerializeStream(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (ns NDJSONSerializer[T]) SerializeStream(w io.Writer, data []T) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range data {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("error encoding ndjson record: %w", err)
+		}
+	}
+	return nil
+}
+
+func (ns NDJSONSerializer[T]) Deserialize(data []byte) (T, error) {
+	var result T
+	err := json.Unmarshal(data, &result)
+	return result, err
+}
+
+type CSVSerializer[T any] struct {}
+
+func (cs CSVSerializer[T]) Serialize(data []T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := cs.SerializeStream(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (cs CSVSerializer[T]) SerializeStream(w io.Writer, data []T) error {
+	writer := csv.NewWriter(w)
+	headerWritten := false
+
+	for _, record := range data {
+		row, ok := any(record).(csvMarshaler)
+		if !ok {
+			return fmt.Errorf("тип %T не поддерживает сериализацию в CSV", record)
+		}
+
+		if !headerWritten {
+			if err := writer.Write(row.csvHeader()); err != nil {
+				return fmt.Errorf("error writing csv header: %w", err)
+			}
+			headerWritten = true
+		}
+
+		if err := writer.Write(row.csvRow()); err != nil {
+			return fmt.Errorf("error writing csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (cs CSVSerializer[T]) Deserialize(data []byte) (T, error) {
+	var result T
+	return result, fmt.Errorf("десериализация из CSV не поддерживается")
+}
+
+type ProtobufSerializer[T any] struct {}
+
+func (ps ProtobufSerializer[T]) Serialize(data []T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ps.SerializeStream(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SerializeStream writes each record as a length-delimited protobuf message,
+// so a client can decode the stream incrementally without buffering it all.
+func (ps ProtobufSerializer[T]) SerializeStream(w io.Writer, data []T) error {
+	var lenBuf [binary.MaxVarintLen64]byte
+
+	for _, record := range data {
+		marshaler, ok := any(record).(protoMarshaler)
+		if !ok {
+			return fmt.Errorf("тип %T не поддерживает сериализацию в protobuf", record)
+		}
+
+		encoded := marshaler.protoMarshal()
+		n := binary.PutUvarint(lenBuf[:], uint64(len(encoded)))
+		if _, err := w.Write(lenBuf[:n]); err != nil {
+			return fmt.Errorf("error writing protobuf frame length: %w", err)
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return fmt.Errorf("error writing protobuf frame: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (ps ProtobufSerializer[T]) Deserialize(data []byte) (T, error) {
+	var result T
+	return result, fmt.Errorf("десериализация из protobuf не поддерживается")
 }
 
 func GetSerializer[T any](format string) (Serializer[T], error) {
@@ -69,25 +291,78 @@ func GetSerializer[T any](format string) (Serializer[T], error) {
 		return JSONSerializer[T]{}, nil
 	case "gob":
 		return GobSerializer[T]{}, nil
+	case "ndjson":
+		return NDJSONSerializer[T]{}, nil
+	case "csv":
+		return CSVSerializer[T]{}, nil
+	case "protobuf":
+		return ProtobufSerializer[T]{}, nil
 	default:
 		return nil, fmt.Errorf("Неизвестный формат сериализации: %s", format)
 	}
-} 
+}
+
+// contentTypeForFormat returns the Content-Type header to set for a
+// negotiated serialization format.
+func contentTypeForFormat(format string) string {
+	switch format {
+	case "ndjson":
+		return "application/x-ndjson"
+	case "csv":
+		return "text/csv"
+	case "protobuf":
+		return "application/x-protobuf"
+	case "gob":
+		return "application/x-gob"
+	default:
+		return "application/json"
+	}
+}
 
-type App struct {
-	DB *pgx.Conn
-	LogChan chan ExecutionLog
-	Wg *sync.WaitGroup
+// negotiateFormat picks the serialization format for a request: an explicit
+// ?format= query parameter wins, otherwise the Accept header is consulted.
+func negotiateFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	accept := r.Header.Get("Accept")
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "application/x-ndjson":
+			return "ndjson"
+		case "text/csv":
+			return "csv"
+		case "application/x-protobuf":
+			return "protobuf"
+		case "application/x-gob":
+			return "gob"
+		case "application/json", "*/*":
+			return "json"
+		}
+	}
+
+	return "json"
 }
 
-type ExecutionLog struct {
-	Id int
-	TaskName string 
-	StartTime time.Time 
-	EndTime time.Time
-	DurationSeconds float64
+type App struct {
+	Pool *pgxpool.Pool
+	ExecutionLogs *repository.ExecutionLogRepository
+	Places *repository.PlacesRepository
+	Logs *LogSink
+	Cache cache.Cache
+	fetchGroup singleflight.Group
+	fetches fetchCoordinators
 }
 
+// ExecutionLog is the app-facing alias of the persisted execution log model.
+type ExecutionLog = repository.ExecutionLog
+
 func main() {
 	envPath := flag.String("env", "", "Путь до файла .env")
 	flag.Parse()
@@ -98,44 +373,54 @@ func main() {
 	}
 
 	ctx := context.Background()
-	conn, err := initDB(ctx)
+	pool, err := initDB(ctx)
 	if err != nil {
 		log.Fatal("Не удалось подключиться к базе данных", err)
 	}
 
-	defer conn.Close(ctx)
+	defer pool.Close()
 
-	logChan := make(chan ExecutionLog, 100)
-	wg := &sync.WaitGroup{}
-	
-	app := &App{
-		DB: conn,
-		LogChan: logChan,
-		Wg: wg,
+	if err := database.Migrate(ctx, pool); err != nil {
+		log.Fatalf("Ошибка применения миграций: %v", err)
 	}
 
-	http.HandleFunc("/all", app.GetAll)
+	logSink := NewLogSink(repository.NewExecutionLogRepository(pool))
+	logSink.Start()
 
-	if err := http.ListenAndServe(":8080", nil); err != nil {
+	placesCache, err := initCache()
+	if err != nil {
+		log.Fatalf("Ошибка инициализации кэша: %v", err)
+	}
+
+	app := &App{
+		Pool: pool,
+		ExecutionLogs: repository.NewExecutionLogRepository(pool),
+		Places: repository.NewPlacesRepository(pool),
+		Logs: logSink,
+		Cache: placesCache,
+	}
+
+	if err := runServer(app, ":8080"); err != nil {
 		fmt.Println("Ошибка запуска сервера:", err)
 	}
 
-	close(logChan)
-	wg.Wait()
-	fmt.Println("Сервер завершил работу")
-}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+
+	if err := logSink.Shutdown(shutdownCtx); err != nil {
+		fmt.Println("Ошибка остановки логирования:", err)
+	}
 
-func (app *App) storeExecutionLog(el ExecutionLog) error {
-	_, err := app.DB.Exec(
-		context.Background(),
-		"INSERT INTO execution_logs (task_name, start_time, end_time, duration_seconds) VALUES ($1, $2, $3, $4)",
-		el.TaskName,
-		el.StartTime,
-		el.EndTime,
-		el.DurationSeconds,
-	)
+	switch c := app.Cache.(type) {
+	case *cache.LFUCache:
+		c.Close()
+	case *cache.RedisCache:
+		if err := c.Close(); err != nil {
+			fmt.Println("Ошибка остановки кэша:", err)
+		}
+	}
 
-	return err
+	fmt.Println("Сервер завершил работу")
 }
 
 func (app *App) LogExecutionTime(taskName string, action func()) {
@@ -146,110 +431,129 @@ func (app *App) LogExecutionTime(taskName string, action func()) {
 	endTime := time.Now()
 	duration := endTime.Sub(startTime).Seconds()
 
-	logEntry := ExecutionLog{
+	app.Logs.Add(ExecutionLog{
 		TaskName: taskName,
 		StartTime: startTime,
 		EndTime: endTime,
 		DurationSeconds: duration,
-	}
-
-	app.Wg.Add(1)
-	app.LogChan <- logEntry
+	})
 }
 
 func (app *App) GetAll(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
 
-	allPlaces, err := fetchAllPlaces()
+	format := negotiateFormat(r)
+	serializer, err := GetSerializer[Place](format)
 	if err != nil {
-		fmt.Printf("Ошибка: %s", err)
+		fmt.Printf("Ощибка: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	format := r.URL.Query().Get("format")
-	if format == "" {
-		format = "json"
-	}
-	serializer, err := GetSerializer[Place](format)
-	if err != nil {
-		fmt.Printf("Ощибка: %s", err)
-		return 
+	w.Header().Set("Content-Type", contentTypeForFormat(format))
+
+	cacheKey := "places:" + format
+
+	if cached, ok, err := app.Cache.Get(r.Context(), cacheKey); err != nil {
+		fmt.Printf("Ошибка чтения из кэша: %s", err)
+	} else if ok {
+		w.Write(cached)
+		app.Logs.Add(ExecutionLog{
+			TaskName: "GetAll",
+			StartTime: startTime,
+			EndTime: time.Now(),
+			DurationSeconds: time.Since(startTime).Seconds(),
+		})
+		return
 	}
 
-	deserializedData, err := serializer.Serialize(allPlaces)
-	if err != nil {
+	if err := app.fetchAndStream(r.Context(), w, serializer, cacheKey); err != nil {
 		fmt.Printf("Ошибка: %s", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
 	}
 
 	endTime := time.Now()
 	duration := endTime.Sub(startTime).Seconds()
 
-	app.storeExecutionLog(ExecutionLog{
+	app.Logs.Add(ExecutionLog{
 		TaskName: "GetAll",
 		StartTime: startTime,
 		EndTime: endTime,
 		DurationSeconds: duration,
 	})
-	w.Write(deserializedData)
 }
 
-func (app *App) proccesLog() {
-	for logEntry := range app.LogChan {
-		err := app.storeExecutionLog(logEntry)
-		if err != nil {
-			fmt.Printf("Ощибка записи лога: %s\n", err)
-		}
-
-		app.Wg.Done()
+// fetchAndStream fetches every KuDaGo place and runs it through serializer
+// straight into w, one record at a time for streaming formats, instead of
+// buffering the whole response before writing anything. The same bytes are
+// teed into a buffer via io.MultiWriter so the complete response can still
+// be cached under cacheKey once serialization finishes.
+func (app *App) fetchAndStream(ctx context.Context, w io.Writer, serializer Serializer[Place], cacheKey string) error {
+	places, err := app.fetchPlaces(ctx)
+	if err != nil {
+		return err
 	}
-}
 
-func fetchAllPlaces() ([]Place, error) {
-	const baseURL = "https://kudago.com/public-api/v1.4/places"
-	var allPlaces []Place
+	var buf bytes.Buffer
+	target := io.MultiWriter(w, &buf)
 
-	client := &http.Client{}
-
-	page := 210
-	for {
-		url := fmt.Sprintf("%s?page=%d", baseURL, page)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %w", err)
-		} 
-
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error sending request: %w", err)
+	if streamer, ok := serializer.(StreamingSerializer[Place]); ok {
+		if err := streamer.SerializeStream(target, places); err != nil {
+			return err
 		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
+	} else {
+		serializedData, err := serializer.Serialize(places)
 		if err != nil {
-			return nil, fmt.Errorf("error reading response body: %w", err)
+			return err
 		}
-
-		var result struct {
-			Results []Place `json:"results"`
-			Next string `json:"next"`
+		if _, err := target.Write(serializedData); err != nil {
+			return err
 		}
+	}
+
+	if err := app.Cache.Set(context.Background(), cacheKey, buf.Bytes(), cacheTTL()); err != nil {
+		fmt.Printf("Ошибка записи в кэш: %s", err)
+	}
 
-		if err := json.Unmarshal(body, &result); err != nil {
-			return nil, fmt.Errorf("error unmarshalling response: %w", err)
+	return nil
+}
+
+// placesFetchKey is the app.fetchGroup/app.fetches key for the upstream
+// KuDaGo fetch. It is format-agnostic, unlike the per-format cache key,
+// since fetchAllPlaces returns the same data regardless of which format a
+// caller negotiated: concurrent requests for different formats still
+// coalesce into a single upstream fetch.
+const placesFetchKey = "places"
+
+// fetchPlaces fetches every KuDaGo place and persists it through
+// app.Places, coalescing every concurrent caller via app.fetchGroup so only
+// one upstream fetch happens at a time regardless of the format each caller
+// negotiated. The fetch runs against a context shared by every caller
+// currently waiting on it (see app.fetches.join): it is cancelled once the
+// last interested caller disconnects, but survives as long as at least one
+// remains, even if the caller that originally triggered it goes away first.
+func (app *App) fetchPlaces(callerCtx context.Context) ([]Place, error) {
+	fetchCtx, leave := app.fetches.join(placesFetchKey, callerCtx)
+	defer leave()
+
+	result, err, _ := app.fetchGroup.Do(placesFetchKey, func() (interface{}, error) {
+		places, err := fetchAllPlaces(fetchCtx)
+		if err != nil {
+			return nil, err
 		}
 
-		allPlaces = append(allPlaces, result.Results...)
-		
-		if result.Next == "" {
-			break 
+		if err := app.Places.UpsertMany(fetchCtx, toRepositoryPlaces(places)); err != nil {
+			fmt.Printf("Ошибка сохранения мест в базу: %s", err)
 		}
 
-		page++
+		return places, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	
-	return allPlaces, nil
+
+	return result.([]Place), nil
 }
 
 func initConfig(envPath string) error {
@@ -263,14 +567,14 @@ func initConfig(envPath string) error {
 	return nil
 }
 
-func initDB(ctx context.Context) (*pgx.Conn, error) {
+func initDB(ctx context.Context) (*pgxpool.Pool, error) {
 	username := viper.GetString("DB_USERNAME")
 	password := viper.GetString("DB_PASSWORD")
 	host := viper.GetString("DB_HOST")
 	port := viper.GetInt("DB_PORT")
 	dbName := viper.GetString("DB_NAME")
 
-	return database.NewPostgresConnection(ctx, database.ConnectionParams{
+	return database.NewPostgresPool(ctx, database.ConnectionParams{
 		Username: username,
 		Password: password,
 		Host: host,
@@ -278,3 +582,33 @@ func initDB(ctx context.Context) (*pgx.Conn, error) {
 		DBName: dbName,
 	})
 }
+
+const (
+	defaultCacheTTL     = 5 * time.Minute
+	lfuMaxItemsPerShard = 256
+	lfuGCInterval       = time.Minute
+)
+
+// initCache builds the Cache backend selected by CACHE_BACKEND (memory or
+// redis, defaulting to memory when unset).
+func initCache() (cache.Cache, error) {
+	switch viper.GetString("CACHE_BACKEND") {
+	case "redis":
+		redisCache, err := cache.NewRedisCache(viper.GetString("REDIS_URL"))
+		if err != nil {
+			return nil, fmt.Errorf("error initializing redis cache: %w", err)
+		}
+		return redisCache, nil
+	default:
+		return cache.NewLFUCache(lfuMaxItemsPerShard, lfuGCInterval), nil
+	}
+}
+
+// cacheTTL returns CACHE_TTL_SECONDS if configured, otherwise defaultCacheTTL.
+func cacheTTL() time.Duration {
+	if seconds := viper.GetInt("CACHE_TTL_SECONDS"); seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return defaultCacheTTL
+}
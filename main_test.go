@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testPlace() Place {
+	return Place{
+		Id:       1,
+		Title:    "Эрмитаж",
+		Slug:     "hermitage",
+		Address:  "Дворцовая площадь, 2",
+		Phone:    "+7 812 000-00-00",
+		Subway:   "Адмиралтейская",
+		IsClosed: false,
+		Location: "spb",
+	}
+}
+
+func TestCSVSerializerSerialize(t *testing.T) {
+	places := []Place{testPlace(), {Id: 2, Title: "Закрыто", IsClosed: true}}
+
+	data, err := CSVSerializer[Place]{}.Serialize(places)
+	if err != nil {
+		t.Fatalf("Serialize вернул ошибку: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\r\n"), "\n")
+	if len(lines) != len(places)+1 {
+		t.Fatalf("ожидалось %d строк, получено %d: %q", len(places)+1, len(lines), data)
+	}
+	if !strings.HasPrefix(lines[0], "id,title,slug,address,phone,subway,is_closed,location") {
+		t.Errorf("неожиданный заголовок CSV: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[2], "2,Закрыто") || !strings.Contains(lines[2], "true") {
+		t.Errorf("неожиданная вторая строка CSV: %q", lines[2])
+	}
+}
+
+func TestCSVSerializerDeserializeUnsupported(t *testing.T) {
+	if _, err := (CSVSerializer[Place]{}).Deserialize(nil); err == nil {
+		t.Error("ожидалась ошибка, десериализация CSV не поддерживается")
+	}
+}
+
+func TestProtobufSerializerRoundTripsFieldNumbers(t *testing.T) {
+	place := testPlace()
+
+	data, err := ProtobufSerializer[Place]{}.Serialize([]Place{place})
+	if err != nil {
+		t.Fatalf("Serialize вернул ошибку: %v", err)
+	}
+
+	length, n := binary.Uvarint(data)
+	if n <= 0 {
+		t.Fatalf("не удалось прочитать длину кадра: %v", data)
+	}
+
+	message := data[n : n+int(length)]
+
+	tag, tagLen := binary.Uvarint(message)
+	if tagLen <= 0 {
+		t.Fatalf("не удалось прочитать тег первого поля: %v", message)
+	}
+	wantTag := uint64(1 << 3) // field 1 (Id), varint wire type
+	if tag != wantTag {
+		t.Errorf("неожиданный тег первого поля: получено %d, ожидалось %d", tag, wantTag)
+	}
+}
+
+func TestNegotiateFormatQueryParamWins(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/all?format=csv", nil)
+	req.Header.Set("Accept", "application/json")
+
+	if got := negotiateFormat(req); got != "csv" {
+		t.Errorf("negotiateFormat = %q, хотели %q", got, "csv")
+	}
+}
+
+func TestNegotiateFormatFromAcceptHeader(t *testing.T) {
+	cases := map[string]string{
+		"application/x-ndjson":         "ndjson",
+		"text/csv":                     "csv",
+		"application/x-protobuf":       "protobuf",
+		"application/x-gob":            "gob",
+		"text/plain, application/json": "json",
+		"text/plain;q=0.9, */*;q=0.1":  "json",
+	}
+
+	for accept, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/all", nil)
+		req.Header.Set("Accept", accept)
+
+		if got := negotiateFormat(req); got != want {
+			t.Errorf("negotiateFormat(Accept=%q) = %q, хотели %q", accept, got, want)
+		}
+	}
+}
+
+func TestNegotiateFormatDefaultsToJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/all", nil)
+
+	if got := negotiateFormat(req); got != "json" {
+		t.Errorf("negotiateFormat без Accept = %q, хотели json", got)
+	}
+}
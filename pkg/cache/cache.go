@@ -0,0 +1,14 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores serialized response bodies keyed by a cache key such as
+// "places:json". Implementations decide their own eviction policy; callers
+// only need Get/Set.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const shardCount = 16
+
+type lfuItem struct {
+	value     []byte
+	expiresAt time.Time
+	frequency int
+}
+
+type lfuShard struct {
+	mu    sync.Mutex
+	items map[string]*lfuItem
+}
+
+// LFUCache is a sharded, in-memory Cache that evicts the least-frequently
+// used entry in a shard once it is full, and sweeps expired entries in the
+// background.
+type LFUCache struct {
+	shards           [shardCount]*lfuShard
+	maxItemsPerShard int
+	stopGC           chan struct{}
+}
+
+func NewLFUCache(maxItemsPerShard int, gcInterval time.Duration) *LFUCache {
+	c := &LFUCache{
+		maxItemsPerShard: maxItemsPerShard,
+		stopGC:           make(chan struct{}),
+	}
+
+	for i := range c.shards {
+		c.shards[i] = &lfuShard{items: make(map[string]*lfuItem)}
+	}
+
+	go c.gcLoop(gcInterval)
+
+	return c
+}
+
+func (c *LFUCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	shard := c.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	item, ok := shard.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(item.expiresAt) {
+		delete(shard.items, key)
+		return nil, false, nil
+	}
+
+	item.frequency++
+	return item.value, true, nil
+}
+
+func (c *LFUCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	shard := c.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if _, exists := shard.items[key]; !exists && len(shard.items) >= c.maxItemsPerShard {
+		shard.evictLeastFrequent()
+	}
+
+	shard.items[key] = &lfuItem{
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	return nil
+}
+
+// Close stops the background GC sweep.
+func (c *LFUCache) Close() {
+	close(c.stopGC)
+}
+
+func (c *LFUCache) shardFor(key string) *lfuShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%shardCount]
+}
+
+func (c *LFUCache) gcLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.sweep()
+		case <-c.stopGC:
+			return
+		}
+	}
+}
+
+func (c *LFUCache) sweep() {
+	now := time.Now()
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		for key, item := range shard.items {
+			if now.After(item.expiresAt) {
+				delete(shard.items, key)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// evictLeastFrequent removes the entry with the lowest hit count in the
+// shard. Caller must hold shard.mu.
+func (s *lfuShard) evictLeastFrequent() {
+	var victimKey string
+	minFrequency := -1
+
+	for key, item := range s.items {
+		if minFrequency == -1 || item.frequency < minFrequency {
+			minFrequency = item.frequency
+			victimKey = key
+		}
+	}
+
+	if victimKey != "" {
+		delete(s.items, victimKey)
+	}
+}
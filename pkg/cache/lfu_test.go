@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLFUCacheSetGetRoundTrip(t *testing.T) {
+	c := NewLFUCache(10, time.Hour)
+	defer c.Close()
+
+	ctx := context.Background()
+	if err := c.Set(ctx, "places:json", []byte("payload"), time.Minute); err != nil {
+		t.Fatalf("Set вернул ошибку: %v", err)
+	}
+
+	value, ok, err := c.Get(ctx, "places:json")
+	if err != nil {
+		t.Fatalf("Get вернул ошибку: %v", err)
+	}
+	if !ok {
+		t.Fatal("ожидалось попадание в кэш")
+	}
+	if string(value) != "payload" {
+		t.Errorf("Get вернул %q, ожидалось %q", value, "payload")
+	}
+}
+
+func TestLFUCacheGetMiss(t *testing.T) {
+	c := NewLFUCache(10, time.Hour)
+	defer c.Close()
+
+	_, ok, err := c.Get(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf("Get вернул ошибку: %v", err)
+	}
+	if ok {
+		t.Error("ожидался промах по отсутствующему ключу")
+	}
+}
+
+func TestLFUCacheExpiredEntryIsEvictedOnGet(t *testing.T) {
+	c := NewLFUCache(10, time.Hour)
+	defer c.Close()
+
+	ctx := context.Background()
+	if err := c.Set(ctx, "key", []byte("v"), time.Millisecond); err != nil {
+		t.Fatalf("Set вернул ошибку: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok, _ := c.Get(ctx, "key"); ok {
+		t.Error("просроченная запись не должна возвращаться")
+	}
+}
+
+func TestLFUCacheSweepRemovesExpiredEntries(t *testing.T) {
+	c := NewLFUCache(10, time.Hour)
+	defer c.Close()
+
+	ctx := context.Background()
+	if err := c.Set(ctx, "key", []byte("v"), time.Millisecond); err != nil {
+		t.Fatalf("Set вернул ошибку: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	c.sweep()
+
+	shard := c.shardFor("key")
+	shard.mu.Lock()
+	_, exists := shard.items["key"]
+	shard.mu.Unlock()
+
+	if exists {
+		t.Error("sweep должен был удалить просроченную запись")
+	}
+}
+
+func TestLFUShardEvictLeastFrequent(t *testing.T) {
+	shard := &lfuShard{items: map[string]*lfuItem{
+		"cold": {value: []byte("cold"), expiresAt: time.Now().Add(time.Hour), frequency: 0},
+		"hot":  {value: []byte("hot"), expiresAt: time.Now().Add(time.Hour), frequency: 5},
+	}}
+
+	shard.mu.Lock()
+	shard.evictLeastFrequent()
+	shard.mu.Unlock()
+
+	if _, exists := shard.items["cold"]; exists {
+		t.Error("ожидалось, что менее частая запись 'cold' будет вытеснена")
+	}
+	if _, exists := shard.items["hot"]; !exists {
+		t.Error("более частая запись 'hot' не должна вытесняться")
+	}
+}
+
+func TestLFUCacheSetEvictsAtCapacity(t *testing.T) {
+	c := NewLFUCache(2, time.Hour)
+	defer c.Close()
+
+	ctx := context.Background()
+	shard := c.shardFor("a")
+
+	// Find two more keys that hash to the same shard as "a" so capacity is
+	// exercised within a single shard, independent of shardCount. With
+	// maxItemsPerShard=2, "a" and "first" fill the shard; "second" then
+	// forces a real least-frequent choice between the two.
+	var others []string
+	for i := 0; len(others) < 2; i++ {
+		candidate := string(rune('b' + i))
+		if c.shardFor(candidate) == shard && candidate != "a" {
+			others = append(others, candidate)
+		}
+	}
+	first, second := others[0], others[1]
+
+	if err := c.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set вернул ошибку: %v", err)
+	}
+	if err := c.Set(ctx, first, []byte("2"), time.Minute); err != nil {
+		t.Fatalf("Set вернул ошибку: %v", err)
+	}
+	// Give "a" a hit so it is not the least-frequent entry once "second" arrives.
+	if _, _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatalf("Get вернул ошибку: %v", err)
+	}
+	if err := c.Set(ctx, second, []byte("3"), time.Minute); err != nil {
+		t.Fatalf("Set вернул ошибку: %v", err)
+	}
+
+	shard.mu.Lock()
+	count := len(shard.items)
+	shard.mu.Unlock()
+
+	if count != 2 {
+		t.Errorf("ожидался 2 элемента в заполненном шарде, получено %d", count)
+	}
+
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Error("более часто запрашиваемый ключ 'a' не должен быть вытеснен")
+	}
+}
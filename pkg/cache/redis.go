@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a Cache backed by a Redis instance, configured from a
+// redis:// DSN (host, password and DB number are all taken from the URL).
+type RedisCache struct {
+	client *redis.Client
+}
+
+func NewRedisCache(dsn string) (*RedisCache, error) {
+	opts, err := redis.ParseURL(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing redis dsn: %w", err)
+	}
+
+	return &RedisCache{client: redis.NewClient(opts)}, nil
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading from redis: %w", err)
+	}
+
+	return value, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("error writing to redis: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const createSchemaMigrationsSQL = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version TEXT PRIMARY KEY,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+// Migrate applies every embedded .sql file under migrations/ that is not yet
+// recorded in schema_migrations, in filename order, each in its own
+// transaction. It is safe to call on every startup.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, createSchemaMigrationsSQL); err != nil {
+		return fmt.Errorf("error creating schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedMigrations(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	names, err := migrationNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+
+		if err := applyMigration(ctx, pool, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, pool *pgxpool.Pool) (map[string]bool, error) {
+	rows, err := pool.Query(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("error scanning migration version: %w", err)
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func migrationNames() ([]string, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading embedded migrations: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func applyMigration(ctx context.Context, pool *pgxpool.Pool, name string) error {
+	sqlBytes, err := migrationFiles.ReadFile("migrations/" + name)
+	if err != nil {
+		return fmt.Errorf("error reading migration %s: %w", name, err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting migration transaction for %s: %w", name, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, string(sqlBytes)); err != nil {
+		return fmt.Errorf("error applying migration %s: %w", name, err)
+	}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", name); err != nil {
+		return fmt.Errorf("error recording migration %s: %w", name, err)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -4,21 +4,23 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ConnectionParams struct {
-	Username string 
-	Password string 
+	Username string
+	Password string
 	Host string
-	Port int 
-	DBName string 
+	Port int
+	DBName string
 }
 
-func NewPostgresConnection(
+// NewPostgresPool opens a connection pool, safe for concurrent use by the
+// HTTP handlers and the execution-log workers alike.
+func NewPostgresPool(
 	ctx context.Context,
 	params ConnectionParams,
-) (*pgx.Conn, error) {
+) (*pgxpool.Pool, error) {
 	connString := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s",
 		params.Username,
@@ -28,5 +30,5 @@ func NewPostgresConnection(
 		params.DBName,
 	)
 
-	return pgx.Connect(ctx, connString)
+	return pgxpool.New(ctx, connString)
 }
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExecutionLog struct {
+	Id int
+	TaskName string
+	StartTime time.Time
+	EndTime time.Time
+	DurationSeconds float64
+}
+
+// ExecutionLogRepository persists ExecutionLog rows through a shared pool.
+// pgx caches the prepared statement for each distinct SQL string the first
+// time it is used, so repeated calls to Insert/InsertBatch reuse it rather
+// than re-parsing the query on every call.
+type ExecutionLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExecutionLogRepository(pool *pgxpool.Pool) *ExecutionLogRepository {
+	return &ExecutionLogRepository{pool: pool}
+}
+
+const insertExecutionLogSQL = `
+INSERT INTO execution_logs (task_name, start_time, end_time, duration_seconds)
+VALUES ($1, $2, $3, $4)`
+
+func (r *ExecutionLogRepository) Insert(ctx context.Context, entry ExecutionLog) error {
+	_, err := r.pool.Exec(
+		ctx,
+		insertExecutionLogSQL,
+		entry.TaskName,
+		entry.StartTime,
+		entry.EndTime,
+		entry.DurationSeconds,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting execution log: %w", err)
+	}
+
+	return nil
+}
+
+// InsertBatch writes several rows in a single multi-row INSERT, used by the
+// log sink's batcher instead of inserting rows one at a time.
+func (r *ExecutionLogRepository) InsertBatch(ctx context.Context, entries []ExecutionLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO execution_logs (task_name, start_time, end_time, duration_seconds) VALUES "
+	args := make([]any, 0, len(entries)*4)
+
+	for i, entry := range entries {
+		if i > 0 {
+			query += ", "
+		}
+		base := i * 4
+		query += fmt.Sprintf("($%d, $%d, $%d, $%d)", base+1, base+2, base+3, base+4)
+		args = append(args, entry.TaskName, entry.StartTime, entry.EndTime, entry.DurationSeconds)
+	}
+
+	if _, err := r.pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("error inserting execution log batch: %w", err)
+	}
+
+	return nil
+}
+
+// List returns execution logs ordered from most to least recent, paginated
+// by limit/offset.
+func (r *ExecutionLogRepository) List(ctx context.Context, limit, offset int) ([]ExecutionLog, error) {
+	rows, err := r.pool.Query(
+		ctx,
+		"SELECT id, task_name, start_time, end_time, duration_seconds FROM execution_logs ORDER BY start_time DESC LIMIT $1 OFFSET $2",
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing execution logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []ExecutionLog
+	for rows.Next() {
+		var entry ExecutionLog
+		if err := rows.Scan(&entry.Id, &entry.TaskName, &entry.StartTime, &entry.EndTime, &entry.DurationSeconds); err != nil {
+			return nil, fmt.Errorf("error scanning execution log: %w", err)
+		}
+		logs = append(logs, entry)
+	}
+
+	return logs, rows.Err()
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Place struct {
+	Id int
+	Title string
+	Slug string
+	Address string
+	Phone string
+	Subway string
+	IsClosed bool
+	Location string
+}
+
+// PlacesRepository caches the upstream KuDaGo places in the places table.
+type PlacesRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPlacesRepository(pool *pgxpool.Pool) *PlacesRepository {
+	return &PlacesRepository{pool: pool}
+}
+
+const upsertPlaceSQL = `
+INSERT INTO places (id, title, slug, address, phone, subway, is_closed, location)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (id) DO UPDATE SET
+	title = EXCLUDED.title,
+	slug = EXCLUDED.slug,
+	address = EXCLUDED.address,
+	phone = EXCLUDED.phone,
+	subway = EXCLUDED.subway,
+	is_closed = EXCLUDED.is_closed,
+	location = EXCLUDED.location`
+
+// UpsertMany writes every place in a single batch round-trip instead of one
+// statement per row.
+func (r *PlacesRepository) UpsertMany(ctx context.Context, places []Place) error {
+	if len(places) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, place := range places {
+		batch.Queue(
+			upsertPlaceSQL,
+			place.Id,
+			place.Title,
+			place.Slug,
+			place.Address,
+			place.Phone,
+			place.Subway,
+			place.IsClosed,
+			place.Location,
+		)
+	}
+
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range places {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("error upserting place: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *PlacesRepository) List(ctx context.Context) ([]Place, error) {
+	rows, err := r.pool.Query(ctx, "SELECT id, title, slug, address, phone, subway, is_closed, location FROM places")
+	if err != nil {
+		return nil, fmt.Errorf("error listing places: %w", err)
+	}
+	defer rows.Close()
+
+	var places []Place
+	for rows.Next() {
+		var place Place
+		if err := rows.Scan(
+			&place.Id,
+			&place.Title,
+			&place.Slug,
+			&place.Address,
+			&place.Phone,
+			&place.Subway,
+			&place.IsClosed,
+			&place.Location,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning place: %w", err)
+		}
+		places = append(places, place)
+	}
+
+	return places, rows.Err()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const shutdownGracePeriod = 10 * time.Second
+
+func newRouter(app *App) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/all", app.GetAll)
+	mux.HandleFunc("/login", app.Login)
+	mux.HandleFunc("/refresh", app.Refresh)
+	mux.HandleFunc("/logs", requireAuth(app.GetLogs))
+
+	return chainMiddleware(mux, withRequestID, withAccessLog, withRecovery, withCORS)
+}
+
+// runServer serves app's router on addr until SIGINT/SIGTERM, then drains
+// in-flight requests before returning.
+func runServer(app *App, addr string) error {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: newRouter(app),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("error running server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("error shutting down server: %w", err)
+		}
+		return nil
+	}
+}